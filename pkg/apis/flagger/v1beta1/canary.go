@@ -0,0 +1,213 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Canary is a specification for a Canary resource
+type Canary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CanarySpec   `json:"spec"`
+	Status CanaryStatus `json:"status,omitempty"`
+}
+
+// CanarySpec is the spec for a Canary resource
+type CanarySpec struct {
+	// SkipAnalysis promotes the canary without analysing it
+	SkipAnalysis bool `json:"skipAnalysis,omitempty"`
+
+	// Analysis defines the validation process of a canary release
+	Analysis *CanaryAnalysis `json:"analysis,omitempty"`
+
+	// Service defines how the application is exposed to clients
+	Service CanaryService `json:"service,omitempty"`
+}
+
+// CanaryService is the spec for routing traffic to a canary
+type CanaryService struct {
+	Port          int32              `json:"port"`
+	PortDiscovery bool               `json:"portDiscovery,omitempty"`
+	TargetPort    intstr.IntOrString `json:"targetPort,omitempty"`
+}
+
+// CanaryAnalysis is the validation process applied to the canary release
+type CanaryAnalysis struct {
+	// Interval is the analysis interval (e.g. 1m, 30s)
+	Interval string `json:"interval,omitempty"`
+
+	// Threshold is the number of failed checks before rollback
+	Threshold int `json:"threshold,omitempty"`
+
+	// MaxWeight is the max traffic percentage routed to the canary
+	MaxWeight int `json:"maxWeight,omitempty"`
+
+	// StepWeight is the traffic percentage increase per iteration
+	StepWeight int `json:"stepWeight,omitempty"`
+
+	// Iterations is the number of validation iterations for A/B and blue/green
+	Iterations int `json:"iterations,omitempty"`
+
+	// Metrics is the list of metric checks run during the analysis
+	Metrics []CanaryMetric `json:"metrics,omitempty"`
+
+	// Webhooks is the list of external checks run during the analysis
+	Webhooks []CanaryWebhook `json:"webhooks,omitempty"`
+
+	// Alerts is the list of alerts fired during the analysis
+	Alerts []CanaryAlert `json:"alerts,omitempty"`
+}
+
+// CanaryMetric defines a metric check applied during the canary analysis
+type CanaryMetric struct {
+	// Name of the metric
+	Name string `json:"name"`
+
+	// Interval represents the time window of the query
+	Interval string `json:"interval,omitempty"`
+
+	// Query is a promql/cloudwatch/datadog query, mutually exclusive with TemplateRef
+	Query string `json:"query,omitempty"`
+
+	// TemplateRef references a MetricTemplate object
+	TemplateRef *CrossNamespaceObjectReference `json:"templateRef,omitempty"`
+
+	// ThresholdRange is the accepted range for the metric value
+	ThresholdRange *CanaryThresholdRange `json:"thresholdRange,omitempty"`
+
+	// Statistical enables a two-sample significance test between the
+	// canary and the primary instead of a fixed ThresholdRange check.
+	// When set, ThresholdRange is ignored for this metric.
+	Statistical *CanaryMetricStatisticalCheck `json:"statistical,omitempty"`
+}
+
+// CanaryThresholdRange defines the range used for metrics validation
+type CanaryThresholdRange struct {
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+// CanaryMetricStatisticalMethod is the two-sample test used to compare the
+// canary and primary metric distributions
+type CanaryMetricStatisticalMethod string
+
+const (
+	// WelchTTestMethod compares the means of two distributions and is best
+	// suited for roughly normal metrics such as latency or rate averages
+	WelchTTestMethod CanaryMetricStatisticalMethod = "welch-t-test"
+
+	// MannWhitneyUMethod is a non-parametric rank test, better suited for
+	// skewed distributions such as request duration
+	MannWhitneyUMethod CanaryMetricStatisticalMethod = "mann-whitney-u"
+)
+
+// CanaryMetricStatisticalCheck configures a two-sample significance test
+// between the canary and the primary metric samples
+type CanaryMetricStatisticalCheck struct {
+	// Method is the statistical test used to compare the two samples
+	// +kubebuilder:default=welch-t-test
+	Method CanaryMetricStatisticalMethod `json:"method,omitempty"`
+
+	// SampleSize is the number of datapoints collected per side before
+	// the test is run, defaults to 30
+	SampleSize int `json:"sampleSize,omitempty"`
+
+	// Alpha is the significance level, a check fails only when the
+	// p-value drops below it, defaults to 0.05
+	Alpha float64 `json:"alpha,omitempty"`
+
+	// MinEffectSize is the minimum relative difference between the
+	// canary and primary means required to fail the check, defaults to 0.1
+	MinEffectSize float64 `json:"minEffectSize,omitempty"`
+}
+
+// CanaryWebhookType can be pre, post or during rollout
+type CanaryWebhookType string
+
+const (
+	ConfirmRolloutHook   CanaryWebhookType = "confirm-rollout"
+	PreRolloutHook       CanaryWebhookType = "pre-rollout"
+	RolloutHook          CanaryWebhookType = "rollout"
+	ConfirmPromotionHook CanaryWebhookType = "confirm-promotion"
+	PostRolloutHook      CanaryWebhookType = "post-rollout"
+	EventHook            CanaryWebhookType = "event"
+
+	// RollbackGateHook is called before the scheduler transitions a canary
+	// from Progressing to Failed, giving an external system a chance to
+	// veto the rollback
+	RollbackGateHook CanaryWebhookType = "rollback-gate"
+)
+
+// CanaryWebhook can be used to gate the canary deployment or to run load tests
+type CanaryWebhook struct {
+	Name     string             `json:"name"`
+	Type     CanaryWebhookType  `json:"type,omitempty"`
+	URL      string             `json:"url"`
+	Timeout  string             `json:"timeout,omitempty"`
+	Metadata *map[string]string `json:"metadata,omitempty"`
+}
+
+// CanaryAlert defines an alert fired for the canary analysis
+type CanaryAlert struct {
+	Name        string                        `json:"name"`
+	Severity    string                        `json:"severity,omitempty"`
+	ProviderRef CrossNamespaceObjectReference `json:"providerRef"`
+
+	// Phases restricts the alert to firing only during the listed canary
+	// phases. When empty the alert fires on every phase.
+	Phases []CanaryPhase `json:"phases,omitempty"`
+
+	// MessageTemplate is a Go text/template string evaluated against the
+	// canary object and the current metric snapshot. When empty, a default
+	// message is used.
+	MessageTemplate string `json:"messageTemplate,omitempty"`
+}
+
+// CrossNamespaceObjectReference is a reference to an object in a possibly
+// different namespace than the referencing one
+type CrossNamespaceObjectReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// CanaryPhase is a label for the condition of a canary at the current time
+type CanaryPhase string
+
+const (
+	CanaryPhaseInitializing CanaryPhase = "Initializing"
+	CanaryPhaseInitialized  CanaryPhase = "Initialized"
+	CanaryPhaseProgressing  CanaryPhase = "Progressing"
+	CanaryPhasePromoting    CanaryPhase = "Promoting"
+	CanaryPhaseFinalising   CanaryPhase = "Finalising"
+	CanaryPhaseSucceeded    CanaryPhase = "Succeeded"
+	CanaryPhaseFailed       CanaryPhase = "Failed"
+)
+
+// MetricSampleBuffer is a rolling window of recent metric samples kept in
+// the canary status so the analyser doesn't have to re-query the metrics
+// backend for history already seen on a previous analysis tick
+type MetricSampleBuffer struct {
+	// CanarySamples are the most recent datapoints collected for the canary
+	CanarySamples []float64 `json:"canarySamples,omitempty"`
+
+	// PrimarySamples are the most recent datapoints collected for the primary
+	PrimarySamples []float64 `json:"primarySamples,omitempty"`
+}
+
+// CanaryStatus is the status for a Canary resource
+type CanaryStatus struct {
+	Phase           CanaryPhase `json:"phase,omitempty"`
+	FailedChecks    int         `json:"failedChecks,omitempty"`
+	CanaryWeight    int         `json:"canaryWeight,omitempty"`
+	Iterations      int         `json:"iterations,omitempty"`
+	LastAppliedSpec string      `json:"lastAppliedSpec,omitempty"`
+
+	// MetricSampleBuffers persists the rolling sample buffers used by
+	// statistical metric checks, keyed by metric name
+	MetricSampleBuffers map[string]MetricSampleBuffer `json:"metricSampleBuffers,omitempty"`
+}
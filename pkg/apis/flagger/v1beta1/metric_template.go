@@ -0,0 +1,42 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MetricTemplate is a specification for a MetricTemplate resource
+type MetricTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MetricTemplateSpec `json:"spec"`
+}
+
+// MetricTemplateSpec is the spec for a MetricTemplate resource
+type MetricTemplateSpec struct {
+	Provider MetricTemplateProvider `json:"provider"`
+	Query    string                 `json:"query"`
+}
+
+// MetricTemplateProvider selects the metrics backend and the query dialect
+// used to evaluate a MetricTemplate
+type MetricTemplateProvider struct {
+	Type      string                         `json:"type"`
+	Address   string                         `json:"address,omitempty"`
+	SecretRef *CrossNamespaceObjectReference `json:"secretRef,omitempty"`
+
+	// ResultSelector picks which MetricDataResult to read the value from
+	// when a CloudWatch query contains more than one MetricDataQuery (e.g.
+	// metric math expressions referencing multiple underlying metrics). It
+	// must match the Id of one of the submitted queries. When empty, the
+	// result whose Id matches the first submitted query is used.
+	ResultSelector string `json:"resultSelector,omitempty"`
+
+	// ResultReducer aggregates the datapoints returned for the selected
+	// result over the requested time window. One of avg, p95, max, min or
+	// last. Defaults to last.
+	ResultReducer string `json:"resultReducer,omitempty"`
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/notifier"
+)
+
+// alertDedupeWindow bounds how often the same canary/alert/phase
+// combination can fire, so repeated RunAnalysis ticks in the same phase
+// don't spam the alert webhook
+const alertDedupeWindow = time.Minute
+
+// notifierFactory builds the Notifier a CanaryAlert should be posted
+// through, typically by resolving its ProviderRef to a provider secret
+type notifierFactory func(flaggerv1.CanaryAlert) (notifier.Notifier, error)
+
+// Alerter dispatches canary alerts through a notifier.Router. It is held by
+// the controller for the lifetime of the process so the router's dedup
+// window is honoured across reconciliation ticks, rather than living behind
+// a package-level singleton that different tests or controller instances
+// can't isolate from one another.
+type Alerter struct {
+	router *notifier.Router
+}
+
+// NewAlerter returns an Alerter whose router suppresses repeat firings of
+// the same canary/alert/phase combination within dedupeWindow
+func NewAlerter(dedupeWindow time.Duration) *Alerter {
+	return &Alerter{router: notifier.NewRouter(dedupeWindow)}
+}
+
+// SendAlerts renders and dispatches every canary alert that matches the
+// current phase and severity, skipping alerts the router considers a
+// repeat of one already sent for this phase within the dedupe window
+func (a *Alerter) SendAlerts(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase, metrics notifier.MetricSnapshot, reason string, severity string, factory notifierFactory) []error {
+	var errs []error
+
+	if canary.Spec.Analysis == nil {
+		return errs
+	}
+
+	for _, alert := range canary.Spec.Analysis.Alerts {
+		if !a.router.ShouldFire(canary, alert, phase, severity) {
+			continue
+		}
+
+		n, err := factory(alert)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("alert %s: %s", alert.Name, err.Error()))
+			continue
+		}
+
+		message, err := notifier.RenderMessage(alert, canary, phase, metrics, reason)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("alert %s: %s", alert.Name, err.Error()))
+			continue
+		}
+
+		workload := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+		if err := n.Post(workload, message, nil, severity); err != nil {
+			errs = append(errs, fmt.Errorf("alert %s: %s", alert.Name, err.Error()))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/notifier"
+)
+
+func alertingTestCanary(alerts []flaggerv1.CanaryAlert) *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Alerts: alerts,
+			},
+		},
+	}
+}
+
+func TestSendAlerts_FiresOnMatchingPhase(t *testing.T) {
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload notifier.SlackPayload
+		require.NoError(t, json.Unmarshal(b, &payload))
+		received = append(received, payload.Attachments[0].Text)
+	}))
+	defer ts.Close()
+
+	canary := alertingTestCanary([]flaggerv1.CanaryAlert{
+		{
+			Name:            "slack-failed",
+			Severity:        "info",
+			Phases:          []flaggerv1.CanaryPhase{flaggerv1.CanaryPhaseFailed},
+			MessageTemplate: "{{ .Canary.Name }} rolled back: {{ .Reason }}",
+		},
+	})
+
+	factory := func(alert flaggerv1.CanaryAlert) (notifier.Notifier, error) {
+		return notifier.NewSlackNotifier(ts.URL, "", "flagger")
+	}
+
+	alerter := NewAlerter(alertDedupeWindow)
+	errs := alerter.SendAlerts(canary, flaggerv1.CanaryPhaseProgressing, nil, "too many failed checks", "error", factory)
+	assert.Empty(t, errs)
+	assert.Empty(t, received, "alert scoped to Failed should not fire on Progressing")
+
+	errs = alerter.SendAlerts(canary, flaggerv1.CanaryPhaseFailed, nil, "too many failed checks", "error", factory)
+	assert.Empty(t, errs)
+	require.Len(t, received, 1)
+	assert.Equal(t, "podinfo rolled back: too many failed checks", received[0])
+}
+
+func TestSendAlerts_DedupesRepeatedTicks(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer ts.Close()
+
+	canary := alertingTestCanary([]flaggerv1.CanaryAlert{
+		{Name: "slack-progressing", Severity: "info"},
+	})
+
+	factory := func(alert flaggerv1.CanaryAlert) (notifier.Notifier, error) {
+		return notifier.NewSlackNotifier(ts.URL, "", "flagger")
+	}
+
+	alerter := NewAlerter(alertDedupeWindow)
+	alerter.SendAlerts(canary, flaggerv1.CanaryPhaseProgressing, nil, "checking", "info", factory)
+	alerter.SendAlerts(canary, flaggerv1.CanaryPhaseProgressing, nil, "checking", "info", factory)
+
+	assert.Equal(t, 1, calls, "a second tick in the same phase should be deduped")
+}
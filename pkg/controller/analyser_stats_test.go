@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestCheckStatisticalSignificance_WelchTTest(t *testing.T) {
+	canary := []float64{120, 125, 130, 128, 122, 131, 127, 124, 129, 126}
+	primary := []float64{100, 102, 98, 101, 99, 103, 97, 100, 101, 99}
+
+	result, err := checkStatisticalSignificance(flaggerv1.CanaryMetricStatisticalCheck{
+		Method:        flaggerv1.WelchTTestMethod,
+		Alpha:         0.05,
+		MinEffectSize: 0.1,
+	}, canary, primary)
+
+	require.NoError(t, err)
+	assert.True(t, result.Failed, "a consistently higher canary latency should fail the check")
+	assert.Less(t, result.PValue, 0.05)
+}
+
+func TestCheckStatisticalSignificance_NoisyEqualMetrics(t *testing.T) {
+	canary := []float64{100, 105, 98, 102, 97, 103, 101, 99, 104, 96}
+	primary := []float64{101, 99, 103, 97, 102, 98, 100, 104, 96, 105}
+
+	result, err := checkStatisticalSignificance(flaggerv1.CanaryMetricStatisticalCheck{
+		Method: flaggerv1.WelchTTestMethod,
+	}, canary, primary)
+
+	require.NoError(t, err)
+	assert.False(t, result.Failed, "noisy but roughly equal metrics should not trigger a rollback")
+}
+
+func TestCheckStatisticalSignificance_MannWhitneyU(t *testing.T) {
+	canary := []float64{5, 7, 6, 8, 9, 6, 7, 8, 9, 10}
+	primary := []float64{1, 2, 1, 3, 2, 1, 2, 3, 1, 2}
+
+	result, err := checkStatisticalSignificance(flaggerv1.CanaryMetricStatisticalCheck{
+		Method: flaggerv1.MannWhitneyUMethod,
+	}, canary, primary)
+
+	require.NoError(t, err)
+	assert.True(t, result.Failed)
+}
+
+func TestCheckStatisticalSignificance_NotEnoughSamples(t *testing.T) {
+	_, err := checkStatisticalSignificance(flaggerv1.CanaryMetricStatisticalCheck{}, []float64{1}, []float64{1, 2})
+	assert.Error(t, err)
+}
+
+func TestEvaluateMetric_StatisticalAccumulatesUntilSampleSize(t *testing.T) {
+	status := &flaggerv1.CanaryStatus{}
+	metric := flaggerv1.CanaryMetric{
+		Name: "request-duration",
+		Statistical: &flaggerv1.CanaryMetricStatisticalCheck{
+			Method:        flaggerv1.WelchTTestMethod,
+			SampleSize:    5,
+			Alpha:         0.05,
+			MinEffectSize: 0.1,
+		},
+	}
+
+	canaryVals := []float64{120, 125, 130, 128, 122}
+	primaryVals := []float64{100, 102, 98, 101, 99}
+
+	var result metricCheckResult
+	var err error
+	for i := range canaryVals {
+		result, err = evaluateMetric(metric, status, canaryVals[i], primaryVals[i])
+		require.NoError(t, err)
+		if i < len(canaryVals)-1 {
+			assert.False(t, result.Failed, "the statistical check must wait for the full sample size before running")
+		}
+	}
+
+	assert.True(t, result.Failed, "a consistently higher canary value should fail once the sample size is reached")
+}
+
+func TestEvaluateMetric_StatisticalPropagatesConfigErrors(t *testing.T) {
+	status := &flaggerv1.CanaryStatus{}
+	metric := flaggerv1.CanaryMetric{
+		Name: "request-duration",
+		Statistical: &flaggerv1.CanaryMetricStatisticalCheck{
+			Method:     "welch-ttest", // typo: should be "welch-t-test"
+			SampleSize: 2,
+		},
+	}
+
+	_, err := evaluateMetric(metric, status, 120, 100)
+	require.NoError(t, err, "the buffer isn't full yet, so the bad Method hasn't been exercised")
+
+	_, err = evaluateMetric(metric, status, 120, 100)
+	assert.Error(t, err, "an unknown statistical method must not be swallowed as a silent pass")
+}
+
+func TestEvaluateMetric_ThresholdRange(t *testing.T) {
+	status := &flaggerv1.CanaryStatus{}
+	metric := flaggerv1.CanaryMetric{
+		Name: "error-rate",
+		ThresholdRange: &flaggerv1.CanaryThresholdRange{
+			Max: toFloatPtr(1),
+		},
+	}
+
+	result, err := evaluateMetric(metric, status, 0.5, 0)
+	require.NoError(t, err)
+	assert.False(t, result.Failed)
+
+	result, err = evaluateMetric(metric, status, 2, 0)
+	require.NoError(t, err)
+	assert.True(t, result.Failed)
+}
+
+func toFloatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestRecordStatisticalSamples_TrimsToSampleSize(t *testing.T) {
+	status := &flaggerv1.CanaryStatus{}
+
+	for i := 0; i < 5; i++ {
+		recordStatisticalSamples(status, "request-duration", float64(i), float64(i), 3)
+	}
+
+	buf := status.MetricSampleBuffers["request-duration"]
+	assert.Len(t, buf.CanarySamples, 3)
+	assert.Equal(t, []float64{2, 3, 4}, buf.CanarySamples)
+}
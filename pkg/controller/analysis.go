@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/notifier"
+)
+
+// MetricProvider returns the current canary and primary values for a
+// CanaryMetric, e.g. by querying whatever backend the metric's
+// TemplateRef/Query points at.
+type MetricProvider func(metric flaggerv1.CanaryMetric) (canaryValue, primaryValue float64, err error)
+
+// severityForPhase is the alert severity RunAnalysis reports for a given
+// outcome phase: a failure is always worth paging on, everything else is
+// informational.
+func severityForPhase(phase flaggerv1.CanaryPhase) string {
+	if phase == flaggerv1.CanaryPhaseFailed {
+		return "error"
+	}
+	return "info"
+}
+
+// RunAnalysis runs one analysis tick for a canary in CanaryPhaseProgressing:
+// it evaluates every configured metric and accumulates FailedChecks; once
+// the configured Threshold is hit it runs any rollback-gate webhooks before
+// flipping the canary to CanaryPhaseFailed, then dispatches any alerts
+// configured for the resulting phase. It is the minimal per-tick entry
+// point evaluateMetric, shouldRollback and Alerter.SendAlerts are actually
+// reachable from, standing in for the body of the upstream scheduler's
+// advanceCanary loop that this tree doesn't carry (no
+// deployer/router/generated clientset).
+func RunAnalysis(canary *flaggerv1.Canary, provider MetricProvider, alerter *Alerter, factory notifierFactory) ([]string, error) {
+	if canary.Spec.Analysis == nil {
+		return nil, nil
+	}
+
+	var failureReasons []string
+	metrics := notifier.MetricSnapshot{}
+	for _, metric := range canary.Spec.Analysis.Metrics {
+		canaryValue, primaryValue, err := provider(metric)
+		if err != nil {
+			return nil, fmt.Errorf("metric %s: %w", metric.Name, err)
+		}
+		metrics[metric.Name] = canaryValue
+
+		result, err := evaluateMetric(metric, &canary.Status, canaryValue, primaryValue)
+		if err != nil {
+			return nil, err
+		}
+		if result.Failed {
+			failureReasons = append(failureReasons, result.Reason)
+		}
+	}
+
+	if len(failureReasons) > 0 {
+		canary.Status.FailedChecks++
+	} else {
+		canary.Status.FailedChecks = 0
+	}
+
+	threshold := canary.Spec.Analysis.Threshold
+	if threshold > 0 && canary.Status.FailedChecks >= threshold {
+		if shouldRollback(canary, failureReasons, metrics) {
+			canary.Status.Phase = flaggerv1.CanaryPhaseFailed
+		}
+	}
+
+	if alerter != nil {
+		alerter.SendAlerts(canary, canary.Status.Phase, metrics, strings.Join(failureReasons, "; "), severityForPhase(canary.Status.Phase), factory)
+	}
+
+	return failureReasons, nil
+}
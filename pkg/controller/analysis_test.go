@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/notifier"
+)
+
+func analysisTestCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Threshold: 2,
+				Metrics: []flaggerv1.CanaryMetric{
+					{
+						Name:           "error-rate",
+						ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: toFloatPtr(1)},
+					},
+				},
+			},
+		},
+		Status: flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing},
+	}
+}
+
+func TestRunAnalysis_FailsAfterThreshold(t *testing.T) {
+	canary := analysisTestCanary()
+	failingProvider := func(metric flaggerv1.CanaryMetric) (float64, float64, error) {
+		return 2, 0, nil
+	}
+
+	reasons, err := RunAnalysis(canary, failingProvider, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, reasons, 1)
+	assert.Equal(t, 1, canary.Status.FailedChecks)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, canary.Status.Phase)
+
+	_, err = RunAnalysis(canary, failingProvider, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, canary.Status.FailedChecks)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, canary.Status.Phase, "the canary should fail once FailedChecks reaches Threshold")
+}
+
+func TestRunAnalysis_PassingTickResetsFailedChecks(t *testing.T) {
+	canary := analysisTestCanary()
+	canary.Status.FailedChecks = 1
+
+	passingProvider := func(metric flaggerv1.CanaryMetric) (float64, float64, error) {
+		return 0.5, 0, nil
+	}
+
+	_, err := RunAnalysis(canary, passingProvider, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, canary.Status.FailedChecks)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, canary.Status.Phase)
+}
+
+func TestRunAnalysis_ProviderErrorIsPropagated(t *testing.T) {
+	canary := analysisTestCanary()
+	erroringProvider := func(metric flaggerv1.CanaryMetric) (float64, float64, error) {
+		return 0, 0, assert.AnError
+	}
+
+	_, err := RunAnalysis(canary, erroringProvider, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestRunAnalysis_RollbackGateDenialResetsFailedChecks(t *testing.T) {
+	gate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(RollbackGateResponse{Approve: false, Reason: "known flaky metric"})
+	}))
+	defer gate.Close()
+
+	canary := analysisTestCanary()
+	canary.Spec.Analysis.Threshold = 1
+	canary.Spec.Analysis.Webhooks = []flaggerv1.CanaryWebhook{
+		{Name: "rollback-gate", Type: flaggerv1.RollbackGateHook, URL: gate.URL},
+	}
+
+	failingProvider := func(metric flaggerv1.CanaryMetric) (float64, float64, error) {
+		return 2, 0, nil
+	}
+
+	_, err := RunAnalysis(canary, failingProvider, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, canary.Status.Phase, "a denied gate must keep the canary in Progressing")
+	assert.Equal(t, 0, canary.Status.FailedChecks, "a denied gate must reset the failed-check counter")
+}
+
+func TestRunAnalysis_DispatchesAlertOnFailure(t *testing.T) {
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload notifier.SlackPayload
+		require.NoError(t, json.Unmarshal(b, &payload))
+		received = append(received, payload.Attachments[0].Text)
+	}))
+	defer ts.Close()
+
+	canary := analysisTestCanary()
+	canary.Spec.Analysis.Threshold = 1
+	canary.Spec.Analysis.Alerts = []flaggerv1.CanaryAlert{
+		{
+			Name:            "slack-failed",
+			Severity:        "info",
+			Phases:          []flaggerv1.CanaryPhase{flaggerv1.CanaryPhaseFailed},
+			MessageTemplate: "{{ .Canary.Name }} rolled back: {{ .Reason }}",
+		},
+	}
+
+	failingProvider := func(metric flaggerv1.CanaryMetric) (float64, float64, error) {
+		return 2, 0, nil
+	}
+	factory := func(alert flaggerv1.CanaryAlert) (notifier.Notifier, error) {
+		return notifier.NewSlackNotifier(ts.URL, "", "flagger")
+	}
+
+	_, err := RunAnalysis(canary, failingProvider, NewAlerter(alertDedupeWindow), factory)
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, canary.Status.Phase)
+	require.Len(t, received, 1, "the Failed-phase alert should fire once RunAnalysis flips the phase")
+	assert.Contains(t, received[0], "rolled back")
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// rollbackGateDefaultTimeout bounds how long the scheduler waits for a
+// rollback gate webhook to respond before treating the rollback as approved
+const rollbackGateDefaultTimeout = 5 * time.Second
+
+// RollbackGateRequest is the payload POSTed to a RollbackGateHook webhook
+// before a canary transitions from Progressing to Failed
+type RollbackGateRequest struct {
+	Name           string             `json:"name"`
+	Namespace      string             `json:"namespace"`
+	Metrics        map[string]float64 `json:"metrics,omitempty"`
+	FailedChecks   int                `json:"failedChecks"`
+	FailureReasons []string           `json:"failureReasons,omitempty"`
+}
+
+// RollbackGateResponse is the expected JSON body of a rollback gate webhook
+type RollbackGateResponse struct {
+	Approve bool   `json:"approve"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// callRollbackGate POSTs the canary's current state to a rollback gate
+// webhook and returns whether the rollback should proceed. A transport
+// error, a non-200 response or a request timeout all approve the rollback,
+// so that a misbehaving gate doesn't wedge the canary in Progressing forever.
+func callRollbackGate(webhook flaggerv1.CanaryWebhook, req RollbackGateRequest) (bool, error) {
+	timeout := rollbackGateDefaultTimeout
+	if webhook.Timeout != "" {
+		d, err := time.ParseDuration(webhook.Timeout)
+		if err != nil {
+			return true, fmt.Errorf("error parsing rollback gate timeout: %s", err.Error())
+		}
+		timeout = d
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return true, fmt.Errorf("error marshaling rollback gate request: %s", err.Error())
+	}
+
+	httpReq, err := http.NewRequest("POST", webhook.URL, bytes.NewBuffer(payload))
+	if err != nil {
+		return true, fmt.Errorf("error creating rollback gate request: %s", err.Error())
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := http.Client{Timeout: timeout}
+	res, err := client.Do(httpReq)
+	if err != nil {
+		return true, fmt.Errorf("error calling rollback gate %s: %s", webhook.Name, err.Error())
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return true, fmt.Errorf("rollback gate %s returned HTTP %v", webhook.Name, res.StatusCode)
+	}
+
+	var gateRes RollbackGateResponse
+	if err := json.NewDecoder(res.Body).Decode(&gateRes); err != nil {
+		return true, fmt.Errorf("error decoding rollback gate response: %s", err.Error())
+	}
+
+	return gateRes.Approve, nil
+}
+
+// shouldRollback runs every configured rollback-gate webhook before the
+// scheduler flips a canary to CanaryPhaseFailed. It approves the rollback
+// unless a gate explicitly denies it; when a gate errors, that gate is
+// treated as approving so a broken endpoint doesn't block rollback. When a
+// gate denies the rollback, FailedChecks is reset to 0 so the canary stays
+// in Progressing and analysis continues instead of failing it.
+func shouldRollback(canary *flaggerv1.Canary, failureReasons []string, metrics map[string]float64) bool {
+	if canary.Spec.Analysis == nil {
+		return true
+	}
+
+	for _, webhook := range canary.Spec.Analysis.Webhooks {
+		if webhook.Type != flaggerv1.RollbackGateHook {
+			continue
+		}
+
+		approve, err := callRollbackGate(webhook, RollbackGateRequest{
+			Name:           canary.Name,
+			Namespace:      canary.Namespace,
+			Metrics:        metrics,
+			FailedChecks:   canary.Status.FailedChecks,
+			FailureReasons: failureReasons,
+		})
+		if err != nil {
+			continue
+		}
+		if !approve {
+			canary.Status.FailedChecks = 0
+			return false
+		}
+	}
+
+	return true
+}
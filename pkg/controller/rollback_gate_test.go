@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func rollbackGateTestCanary(webhookURL string) *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Webhooks: []flaggerv1.CanaryWebhook{
+					{Name: "rollback-gate", Type: flaggerv1.RollbackGateHook, URL: webhookURL},
+				},
+			},
+		},
+		Status: flaggerv1.CanaryStatus{
+			Phase:        flaggerv1.CanaryPhaseProgressing,
+			FailedChecks: 10,
+		},
+	}
+}
+
+func TestShouldRollback_ApprovesByDefault(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var req RollbackGateRequest
+		require.NoError(t, json.Unmarshal(b, &req))
+		assert.Equal(t, "podinfo", req.Name)
+		assert.Equal(t, "default", req.Namespace)
+		assert.Equal(t, 10, req.FailedChecks)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(RollbackGateResponse{Approve: true})
+	}))
+	defer ts.Close()
+
+	canary := rollbackGateTestCanary(ts.URL)
+	assert.True(t, shouldRollback(canary, []string{"fail"}, nil))
+}
+
+func TestShouldRollback_DeniedGateResetsRollback(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(RollbackGateResponse{Approve: false, Reason: "known flaky metric"})
+	}))
+	defer ts.Close()
+
+	canary := rollbackGateTestCanary(ts.URL)
+	assert.False(t, shouldRollback(canary, []string{"fail"}, nil))
+	assert.Equal(t, 0, canary.Status.FailedChecks, "a denied rollback should reset the failed-check counter so analysis continues")
+}
+
+func TestShouldRollback_GateErrorApproves(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	canary := rollbackGateTestCanary(ts.URL)
+	assert.True(t, shouldRollback(canary, []string{"fail"}, nil), "a broken gate should not block rollback")
+}
+
+func TestShouldRollback_NoGateConfiguredApproves(t *testing.T) {
+	canary := rollbackGateTestCanary("")
+	canary.Spec.Analysis.Webhooks = nil
+	assert.True(t, shouldRollback(canary, []string{"fail"}, nil))
+}
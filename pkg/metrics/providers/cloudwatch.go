@@ -3,7 +3,9 @@ package providers
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -18,11 +20,20 @@ import (
 const (
 	cloudWatchMaxRetries                           = 3
 	cloudWatchStartDeltaMultiplierOnMetricInterval = 10
+
+	cloudWatchDefaultReducer = "last"
+
+	// cloudWatchMaxPages bounds how many GetMetricData pages collectValues
+	// will follow via NextToken, so an endpoint that never stops paginating
+	// can't hang a reconcile tick indefinitely
+	cloudWatchMaxPages = 100
 )
 
 type CloudWatchProvider struct {
-	client     cloudWatchClient
-	startDelta time.Duration
+	client         cloudWatchClient
+	startDelta     time.Duration
+	resultSelector string
+	resultReducer  string
 }
 
 // for the testing purpose
@@ -33,8 +44,7 @@ type cloudWatchClient interface {
 // NewCloudWatchProvider takes a metricInterval, a provider spec and the credentials map, and
 // returns a cloudWatchProvider ready to execute queries against the AWS CloudWatch metrics
 func NewCloudWatchProvider(metricInterval string, provider flaggerv1.MetricTemplateProvider) (*CloudWatchProvider, error) {
-	region := strings.TrimLeft(provider.Address, "monitoring.")
-	region = strings.TrimRight(region, ".amazonaws.com")
+	region := cloudWatchRegionFromAddress(provider.Address)
 	sess, err := session.NewSession(
 		aws.NewConfig().
 			WithRegion(region).
@@ -47,14 +57,36 @@ func NewCloudWatchProvider(metricInterval string, provider flaggerv1.MetricTempl
 		return nil, fmt.Errorf("error parsing metric interval: %s", err.Error())
 	}
 
+	reducer := provider.ResultReducer
+	if reducer == "" {
+		reducer = cloudWatchDefaultReducer
+	}
+
 	return &CloudWatchProvider{
-		client:     cloudwatch.New(sess),
-		startDelta: cloudWatchStartDeltaMultiplierOnMetricInterval * md,
+		client:         cloudwatch.New(sess),
+		startDelta:     cloudWatchStartDeltaMultiplierOnMetricInterval * md,
+		resultSelector: provider.ResultSelector,
+		resultReducer:  reducer,
 	}, err
 }
 
-// RunQuery executes the aws cloud watch metrics query against GetMetricsData endpoint
-// and returns the the first result as float64
+// cloudWatchRegionFromAddress strips the "monitoring." prefix and
+// ".amazonaws.com" suffix off a CloudWatch endpoint to recover the region,
+// e.g. "monitoring.us-east-1.amazonaws.com" -> "us-east-1". Using
+// strings.TrimLeft/TrimRight here would be wrong as those trim arbitrary
+// cutsets of characters rather than a prefix/suffix, mangling regions that
+// share letters with the cutset (e.g. "us-east-1").
+func cloudWatchRegionFromAddress(address string) string {
+	region := strings.TrimPrefix(address, "monitoring.")
+	region = strings.TrimSuffix(region, ".amazonaws.com")
+	return region
+}
+
+// RunQuery executes the aws cloud watch metrics query against the GetMetricData endpoint,
+// paginating through NextToken until the full result set is collected, and returns the
+// reduced value (avg, p95, max, min or last) of the selected MetricDataResult.
+// The query may contain multiple MetricDataQuery entries, including Expression entries
+// doing metric math (e.g. errors/requests*100); ResultSelector picks which one to read.
 func (p *CloudWatchProvider) RunQuery(query string) (float64, error) {
 	var cq []*cloudwatch.MetricDataQuery
 	if err := json.Unmarshal([]byte(query), &cq); err != nil {
@@ -63,28 +95,160 @@ func (p *CloudWatchProvider) RunQuery(query string) (float64, error) {
 
 	end := time.Now()
 	start := end.Add(-p.startDelta)
-	res, err := p.client.GetMetricData(&cloudwatch.GetMetricDataInput{
-		EndTime:           aws.Time(end),
-		MaxDatapoints:     aws.Int64(20),
-		StartTime:         aws.Time(start),
-		MetricDataQueries: cq,
-	})
+	if period := cloudWatchQueryPeriod(cq); period > 0 {
+		start = end.Add(-period)
+	}
 
+	values, err := p.collectValues(start, end, cq)
 	if err != nil {
-		return 0, fmt.Errorf("error requesting cloudwatch: %s", err.Error())
+		return 0, err
 	}
 
-	mr := res.MetricDataResults
-	if len(mr) < 1 {
-		return 0, fmt.Errorf("no values found in response: %s", res.String())
+	if len(values) < 1 {
+		return 0, fmt.Errorf("no values found in response for result id %q", p.resultSelectorOrDefault(cq))
 	}
 
-	vs := res.MetricDataResults[0].Values
-	if len(vs) < 1 {
-		return 0, fmt.Errorf("no values found in response: %s", res.String())
+	return reduceValues(values, p.resultReducer)
+}
+
+// collectValues runs GetMetricData, following NextToken until the full
+// result set for the selected query id has been retrieved, up to
+// cloudWatchMaxPages pages
+func (p *CloudWatchProvider) collectValues(start, end time.Time, cq []*cloudwatch.MetricDataQuery) ([]float64, error) {
+	resultID := p.resultSelectorOrDefault(cq)
+
+	var values []float64
+	var nextToken *string
+	for page := 0; ; page++ {
+		if page >= cloudWatchMaxPages {
+			return nil, fmt.Errorf("cloudwatch query for result id %q did not finish paginating after %d pages", resultID, cloudWatchMaxPages)
+		}
+
+		res, err := p.client.GetMetricData(&cloudwatch.GetMetricDataInput{
+			EndTime:           aws.Time(end),
+			MaxDatapoints:     aws.Int64(20),
+			StartTime:         aws.Time(start),
+			MetricDataQueries: cq,
+			NextToken:         nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error requesting cloudwatch: %s", err.Error())
+		}
+
+		mr := res.MetricDataResults
+		if len(mr) < 1 {
+			return nil, fmt.Errorf("no values found in response: %s", res.String())
+		}
+
+		result := selectMetricDataResult(mr, resultID)
+		if result == nil {
+			return nil, fmt.Errorf("no result found for id %q in response: %s", resultID, res.String())
+		}
+
+		for _, v := range result.Values {
+			values = append(values, aws.Float64Value(v))
+		}
+
+		if res.NextToken == nil || aws.StringValue(res.NextToken) == "" {
+			break
+		}
+		nextToken = res.NextToken
 	}
 
-	return aws.Float64Value(vs[0]), nil
+	return values, nil
+}
+
+// resultSelectorOrDefault returns the configured ResultSelector, falling
+// back to the id of the first submitted query when unset
+func (p *CloudWatchProvider) resultSelectorOrDefault(cq []*cloudwatch.MetricDataQuery) string {
+	if p.resultSelector != "" {
+		return p.resultSelector
+	}
+	if len(cq) > 0 {
+		return aws.StringValue(cq[0].Id)
+	}
+	return ""
+}
+
+// selectMetricDataResult returns the result whose Id matches resultID, or
+// the first result when resultID is empty
+func selectMetricDataResult(results []*cloudwatch.MetricDataResult, resultID string) *cloudwatch.MetricDataResult {
+	if resultID == "" {
+		return results[0]
+	}
+	for _, r := range results {
+		if aws.StringValue(r.Id) == resultID {
+			return r
+		}
+	}
+	return nil
+}
+
+// cloudWatchQueryPeriod returns the Period of the first metric stat query
+// that declares one, so the provider honors the query's own window instead
+// of always falling back to the metric interval based default
+func cloudWatchQueryPeriod(cq []*cloudwatch.MetricDataQuery) time.Duration {
+	for _, q := range cq {
+		if q.MetricStat != nil && q.MetricStat.Period != nil {
+			return time.Duration(aws.Int64Value(q.MetricStat.Period)) * time.Second
+		}
+	}
+	return 0
+}
+
+// reduceValues aggregates a window of datapoints down to a single value
+// using the requested reducer
+func reduceValues(values []float64, reducer string) (float64, error) {
+	if len(values) == 0 {
+		return 0, fmt.Errorf("no values to reduce")
+	}
+
+	switch reducer {
+	case "avg":
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values)), nil
+	case "max":
+		max := values[0]
+		for _, v := range values[1:] {
+			if v > max {
+				max = v
+			}
+		}
+		return max, nil
+	case "min":
+		min := values[0]
+		for _, v := range values[1:] {
+			if v < min {
+				min = v
+			}
+		}
+		return min, nil
+	case "p95":
+		return percentile(values, 0.95), nil
+	case "last", "":
+		return values[len(values)-1], nil
+	default:
+		return 0, fmt.Errorf("unknown reducer %q", reducer)
+	}
+}
+
+// percentile returns the nearest-rank percentile of a copy of values
+func percentile(values []float64, p float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
 }
 
 // IsOnline calls GetMetricsData endpoint with the empty query
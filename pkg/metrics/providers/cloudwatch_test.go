@@ -0,0 +1,183 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCloudWatchClient struct {
+	pages []*cloudwatch.GetMetricDataOutput
+	calls int
+}
+
+func (f *fakeCloudWatchClient) GetMetricData(input *cloudwatch.GetMetricDataInput) (*cloudwatch.GetMetricDataOutput, error) {
+	out := f.pages[f.calls]
+	f.calls++
+	return out, nil
+}
+
+func newTestProvider(client cloudWatchClient, resultSelector, resultReducer string) *CloudWatchProvider {
+	return &CloudWatchProvider{
+		client:         client,
+		startDelta:     0,
+		resultSelector: resultSelector,
+		resultReducer:  resultReducer,
+	}
+}
+
+func TestCloudWatchRegionFromAddress(t *testing.T) {
+	assert.Equal(t, "us-east-1", cloudWatchRegionFromAddress("monitoring.us-east-1.amazonaws.com"))
+	assert.Equal(t, "eu-west-1", cloudWatchRegionFromAddress("monitoring.eu-west-1.amazonaws.com"))
+}
+
+func TestCloudWatchProvider_RunQuery_SingleResult(t *testing.T) {
+	client := &fakeCloudWatchClient{
+		pages: []*cloudwatch.GetMetricDataOutput{
+			{
+				MetricDataResults: []*cloudwatch.MetricDataResult{
+					{
+						Id:     aws.String("m1"),
+						Values: []*float64{aws.Float64(1), aws.Float64(2), aws.Float64(3)},
+					},
+				},
+			},
+		},
+	}
+	p := newTestProvider(client, "", "last")
+
+	cq, err := json.Marshal([]*cloudwatch.MetricDataQuery{{Id: aws.String("m1")}})
+	require.NoError(t, err)
+
+	v, err := p.RunQuery(string(cq))
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), v)
+}
+
+func TestCloudWatchProvider_RunQuery_ResultSelector(t *testing.T) {
+	client := &fakeCloudWatchClient{
+		pages: []*cloudwatch.GetMetricDataOutput{
+			{
+				MetricDataResults: []*cloudwatch.MetricDataResult{
+					{Id: aws.String("errors"), Values: []*float64{aws.Float64(5)}},
+					{Id: aws.String("errorRate"), Values: []*float64{aws.Float64(1), aws.Float64(2)}},
+				},
+			},
+		},
+	}
+	p := newTestProvider(client, "errorRate", "avg")
+
+	cq, err := json.Marshal([]*cloudwatch.MetricDataQuery{
+		{Id: aws.String("requests")},
+		{Id: aws.String("errors")},
+		{Id: aws.String("errorRate"), Expression: aws.String("errors/requests*100")},
+	})
+	require.NoError(t, err)
+
+	v, err := p.RunQuery(string(cq))
+	require.NoError(t, err)
+	assert.Equal(t, float64(1.5), v)
+}
+
+func TestCloudWatchProvider_RunQuery_Pagination(t *testing.T) {
+	client := &fakeCloudWatchClient{
+		pages: []*cloudwatch.GetMetricDataOutput{
+			{
+				MetricDataResults: []*cloudwatch.MetricDataResult{
+					{Id: aws.String("m1"), Values: []*float64{aws.Float64(1), aws.Float64(2)}},
+				},
+				NextToken: aws.String("page2"),
+			},
+			{
+				MetricDataResults: []*cloudwatch.MetricDataResult{
+					{Id: aws.String("m1"), Values: []*float64{aws.Float64(10)}},
+				},
+			},
+		},
+	}
+	p := newTestProvider(client, "", "max")
+
+	cq, err := json.Marshal([]*cloudwatch.MetricDataQuery{{Id: aws.String("m1")}})
+	require.NoError(t, err)
+
+	v, err := p.RunQuery(string(cq))
+	require.NoError(t, err)
+	assert.Equal(t, float64(10), v)
+	assert.Equal(t, 2, client.calls)
+}
+
+type fakeEndlessCloudWatchClient struct {
+	calls int
+}
+
+func (f *fakeEndlessCloudWatchClient) GetMetricData(input *cloudwatch.GetMetricDataInput) (*cloudwatch.GetMetricDataOutput, error) {
+	f.calls++
+	return &cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []*cloudwatch.MetricDataResult{
+			{Id: aws.String("m1"), Values: []*float64{aws.Float64(1)}},
+		},
+		NextToken: aws.String("keep-going"),
+	}, nil
+}
+
+func TestCloudWatchProvider_RunQuery_PaginationCap(t *testing.T) {
+	client := &fakeEndlessCloudWatchClient{}
+	p := newTestProvider(client, "", "last")
+
+	cq, err := json.Marshal([]*cloudwatch.MetricDataQuery{{Id: aws.String("m1")}})
+	require.NoError(t, err)
+
+	_, err = p.RunQuery(string(cq))
+	assert.Error(t, err)
+	assert.Equal(t, cloudWatchMaxPages, client.calls, "collectValues should stop following NextToken after the page cap")
+}
+
+func TestCloudWatchProvider_RunQuery_MissingResultID(t *testing.T) {
+	client := &fakeCloudWatchClient{
+		pages: []*cloudwatch.GetMetricDataOutput{
+			{
+				MetricDataResults: []*cloudwatch.MetricDataResult{
+					{Id: aws.String("m1"), Values: []*float64{aws.Float64(1)}},
+				},
+			},
+		},
+	}
+	p := newTestProvider(client, "missing", "last")
+
+	cq, err := json.Marshal([]*cloudwatch.MetricDataQuery{{Id: aws.String("m1")}})
+	require.NoError(t, err)
+
+	_, err = p.RunQuery(string(cq))
+	assert.Error(t, err)
+}
+
+func TestReduceValues(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5}
+
+	avg, err := reduceValues(values, "avg")
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), avg)
+
+	max, err := reduceValues(values, "max")
+	require.NoError(t, err)
+	assert.Equal(t, float64(5), max)
+
+	min, err := reduceValues(values, "min")
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), min)
+
+	last, err := reduceValues(values, "last")
+	require.NoError(t, err)
+	assert.Equal(t, float64(5), last)
+
+	p95, err := reduceValues(values, "p95")
+	require.NoError(t, err)
+	assert.Equal(t, float64(5), p95)
+
+	_, err = reduceValues(values, "bogus")
+	assert.Error(t, err)
+}
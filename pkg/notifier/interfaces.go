@@ -0,0 +1,14 @@
+package notifier
+
+// Notifier can post a canary analysis message to an external system
+// (Slack, Discord, MS Teams, etc)
+type Notifier interface {
+	Post(workload string, message string, fields []Field, severity string) error
+}
+
+// Field is a key/value pair rendered alongside a notification message,
+// e.g. a metric name and its current value
+type Field struct {
+	Name  string
+	Value string
+}
@@ -0,0 +1,138 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"text/template"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// severityRank orders alert severities so a minimum threshold can be
+// compared against the severity of the event being reported
+var severityRank = map[string]int{
+	"info":  0,
+	"warn":  1,
+	"error": 2,
+}
+
+// MetricSnapshot is the set of metric values available when an alert is
+// evaluated, keyed by metric name
+type MetricSnapshot map[string]float64
+
+// alertContext is the data made available to an alert's message template
+type alertContext struct {
+	Canary  *flaggerv1.Canary
+	Phase   flaggerv1.CanaryPhase
+	Metrics MetricSnapshot
+	Reason  string
+}
+
+// Router decides, for a given canary phase and event severity, which of a
+// canary's alerts should fire, renders their message template and
+// deduplicates repeated firings so that a webhook isn't spammed every
+// analysis tick while the canary sits in the same phase.
+type Router struct {
+	mu           sync.Mutex
+	lastFired    map[string]time.Time
+	dedupeWindow time.Duration
+}
+
+// NewRouter returns a Router that suppresses repeat firings of the same
+// canary/alert/phase combination within dedupeWindow
+func NewRouter(dedupeWindow time.Duration) *Router {
+	return &Router{
+		lastFired:    make(map[string]time.Time),
+		dedupeWindow: dedupeWindow,
+	}
+}
+
+// ShouldFire reports whether alert should fire for the given phase and
+// severity, recording the firing for deduplication purposes when it does
+func (r *Router) ShouldFire(canary *flaggerv1.Canary, alert flaggerv1.CanaryAlert, phase flaggerv1.CanaryPhase, severity string) bool {
+	if !phaseMatches(alert.Phases, phase) {
+		return false
+	}
+	if !severityAtLeast(severity, alert.Severity) {
+		return false
+	}
+
+	key := fmt.Sprintf("%s/%s/%s/%s", canary.Namespace, canary.Name, alert.Name, phase)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pruneExpiredLocked()
+
+	if last, ok := r.lastFired[key]; ok && timeNow().Sub(last) < r.dedupeWindow {
+		return false
+	}
+	r.lastFired[key] = timeNow()
+	return true
+}
+
+// pruneExpiredLocked drops entries older than the dedupe window so
+// lastFired doesn't grow for the lifetime of the process as canaries come
+// and go. Callers must hold r.mu.
+func (r *Router) pruneExpiredLocked() {
+	now := timeNow()
+	for key, last := range r.lastFired {
+		if now.Sub(last) >= r.dedupeWindow {
+			delete(r.lastFired, key)
+		}
+	}
+}
+
+// timeNow is a seam so tests can control dedup windows deterministically
+var timeNow = time.Now
+
+func phaseMatches(phases []flaggerv1.CanaryPhase, phase flaggerv1.CanaryPhase) bool {
+	if len(phases) == 0 {
+		return true
+	}
+	for _, p := range phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+func severityAtLeast(severity, minimum string) bool {
+	s, ok := severityRank[severity]
+	if !ok {
+		s = severityRank["info"]
+	}
+	m, ok := severityRank[minimum]
+	if !ok {
+		m = severityRank["info"]
+	}
+	return s >= m
+}
+
+// RenderMessage evaluates an alert's MessageTemplate against the canary,
+// phase and metric snapshot, falling back to reason when no template is set
+func RenderMessage(alert flaggerv1.CanaryAlert, canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase, metrics MetricSnapshot, reason string) (string, error) {
+	if alert.MessageTemplate == "" {
+		return reason, nil
+	}
+
+	tmpl, err := template.New(alert.Name).Parse(alert.MessageTemplate)
+	if err != nil {
+		return "", fmt.Errorf("error parsing alert template: %s", err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alertContext{
+		Canary:  canary,
+		Phase:   phase,
+		Metrics: metrics,
+		Reason:  reason,
+	}); err != nil {
+		return "", fmt.Errorf("error executing alert template: %s", err.Error())
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,88 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func testCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+	}
+}
+
+func TestRouter_ShouldFire_PhaseFilter(t *testing.T) {
+	router := NewRouter(time.Minute)
+	canary := testCanary()
+
+	alert := flaggerv1.CanaryAlert{
+		Name:     "slack",
+		Severity: "info",
+		Phases:   []flaggerv1.CanaryPhase{flaggerv1.CanaryPhaseFailed},
+	}
+
+	assert.False(t, router.ShouldFire(canary, alert, flaggerv1.CanaryPhaseProgressing, "info"))
+	assert.True(t, router.ShouldFire(canary, alert, flaggerv1.CanaryPhaseFailed, "info"))
+}
+
+func TestRouter_ShouldFire_SeverityThreshold(t *testing.T) {
+	router := NewRouter(time.Minute)
+	canary := testCanary()
+
+	alert := flaggerv1.CanaryAlert{Name: "slack", Severity: "error"}
+
+	assert.False(t, router.ShouldFire(canary, alert, flaggerv1.CanaryPhaseProgressing, "info"))
+	assert.True(t, router.ShouldFire(canary, alert, flaggerv1.CanaryPhaseProgressing, "error"))
+}
+
+func TestRouter_ShouldFire_Dedupe(t *testing.T) {
+	router := NewRouter(time.Hour)
+	canary := testCanary()
+	alert := flaggerv1.CanaryAlert{Name: "slack", Severity: "info"}
+
+	assert.True(t, router.ShouldFire(canary, alert, flaggerv1.CanaryPhaseProgressing, "info"))
+	assert.False(t, router.ShouldFire(canary, alert, flaggerv1.CanaryPhaseProgressing, "info"), "repeat firing within the dedupe window should be suppressed")
+}
+
+func TestRouter_ShouldFire_PrunesExpiredEntries(t *testing.T) {
+	router := NewRouter(time.Minute)
+	canary := testCanary()
+	alert := flaggerv1.CanaryAlert{Name: "slack", Severity: "info"}
+
+	real := timeNow
+	defer func() { timeNow = real }()
+
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	assert.True(t, router.ShouldFire(canary, alert, flaggerv1.CanaryPhaseProgressing, "info"))
+
+	timeNow = func() time.Time { return now.Add(2 * time.Minute) }
+	assert.True(t, router.ShouldFire(canary, alert, flaggerv1.CanaryPhaseFailed, "info"))
+
+	router.mu.Lock()
+	defer router.mu.Unlock()
+	assert.Len(t, router.lastFired, 1, "the expired Progressing entry should have been pruned")
+}
+
+func TestRenderMessage_DefaultsToReason(t *testing.T) {
+	msg, err := RenderMessage(flaggerv1.CanaryAlert{}, testCanary(), flaggerv1.CanaryPhaseFailed, nil, "metric check failed")
+	require.NoError(t, err)
+	assert.Equal(t, "metric check failed", msg)
+}
+
+func TestRenderMessage_Template(t *testing.T) {
+	alert := flaggerv1.CanaryAlert{
+		Name:            "slack",
+		MessageTemplate: "{{ .Canary.Name }} is {{ .Phase }}: {{ .Reason }} (p99={{ .Metrics.p99 }})",
+	}
+
+	msg, err := RenderMessage(alert, testCanary(), flaggerv1.CanaryPhaseFailed, MetricSnapshot{"p99": 250}, "latency check failed")
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo is Failed: latency check failed (p99=250)", msg)
+}
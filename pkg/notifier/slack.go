@@ -0,0 +1,103 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type SlackPayload struct {
+	Channel     string            `json:"channel"`
+	Username    string            `json:"username"`
+	IconUrl     string            `json:"icon_url"`
+	Attachments []SlackAttachment `json:"attachments"`
+}
+
+type SlackAttachment struct {
+	Color      string       `json:"color"`
+	AuthorName string       `json:"author_name"`
+	Text       string       `json:"text"`
+	Fields     []SlackField `json:"fields"`
+}
+
+type SlackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// SlackNotifier posts canary analysis events to a Slack incoming webhook
+type SlackNotifier struct {
+	URL      string
+	Channel  string
+	Username string
+}
+
+// NewSlackNotifier validates the Slack URL and returns a SlackNotifier
+func NewSlackNotifier(url string, channel string, username string) (*SlackNotifier, error) {
+	if url == "" {
+		return nil, fmt.Errorf("slack URL is empty")
+	}
+
+	return &SlackNotifier{
+		URL:      url,
+		Channel:  channel,
+		Username: username,
+	}, nil
+}
+
+// Post sends a message to Slack, attaching the given fields as a table and
+// color coding the attachment based on the severity
+func (s *SlackNotifier) Post(workload string, message string, fields []Field, severity string) error {
+	color := "good"
+	if severity == "error" {
+		color = "danger"
+	} else if severity == "warn" {
+		color = "warning"
+	}
+
+	payload := SlackPayload{
+		Channel:  s.Channel,
+		Username: s.Username,
+		Attachments: []SlackAttachment{
+			{
+				Color:      color,
+				AuthorName: workload,
+				Text:       message,
+				Fields:     toSlackFields(fields),
+			},
+		},
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshaling slack payload: %s", err.Error())
+	}
+
+	req, err := http.NewRequest("POST", s.URL, bytes.NewBuffer(b))
+	if err != nil {
+		return fmt.Errorf("error creating slack request: %s", err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error posting to slack: %s", err.Error())
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code posting to slack: %v", res.StatusCode)
+	}
+
+	return nil
+}
+
+func toSlackFields(fields []Field) []SlackField {
+	sf := make([]SlackField, 0, len(fields))
+	for _, f := range fields {
+		sf = append(sf, SlackField{Title: f.Name, Value: f.Value, Short: true})
+	}
+	return sf
+}
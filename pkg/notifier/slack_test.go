@@ -0,0 +1,36 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackNotifier_Post(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var payload SlackPayload
+		require.NoError(t, json.Unmarshal(b, &payload))
+		assert.Equal(t, "podinfo.default", payload.Attachments[0].AuthorName)
+		assert.Equal(t, "danger", payload.Attachments[0].Color)
+	}))
+	defer ts.Close()
+
+	n, err := NewSlackNotifier(ts.URL, "#general", "flagger")
+	require.NoError(t, err)
+
+	err = n.Post("podinfo.default", "canary failed", []Field{{Name: "p99", Value: "250ms"}}, "error")
+	require.NoError(t, err)
+}
+
+func TestNewSlackNotifier_RequiresURL(t *testing.T) {
+	_, err := NewSlackNotifier("", "#general", "flagger")
+	assert.Error(t, err)
+}